@@ -0,0 +1,235 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+)
+
+// timestampPrecision controls how many fractional-second digits formatScalar
+// renders for TIMESTAMP values.
+type timestampPrecision string
+
+const (
+	precisionSeconds timestampPrecision = "seconds"
+	precisionMillis  timestampPrecision = "millis"
+	precisionMicros  timestampPrecision = "micros"
+	precisionNanos   timestampPrecision = "nanos"
+)
+
+// parseTimestampPrecision validates the -timestamp-precision flag value.
+func parseTimestampPrecision(precision string) (timestampPrecision, error) {
+	switch timestampPrecision(precision) {
+	case precisionSeconds, precisionMillis, precisionMicros, precisionNanos:
+		return timestampPrecision(precision), nil
+	default:
+		return "", fmt.Errorf("[parseTimestampPrecision] Unsupported Timestamp Precision: %s", precision)
+	}
+}
+
+// layout returns the time.Format layout for an RFC3339 timestamp truncated
+// to this precision's fractional-second digits.
+func (p timestampPrecision) layout() string {
+	switch p {
+	case precisionSeconds:
+		return "2006-01-02T15:04:05Z07:00"
+	case precisionMillis:
+		return "2006-01-02T15:04:05.000Z07:00"
+	case precisionMicros:
+		return "2006-01-02T15:04:05.000000Z07:00"
+	case precisionNanos:
+		return "2006-01-02T15:04:05.000000000Z07:00"
+	default:
+		return time.RFC3339
+	}
+}
+
+//---------------------------------------------------------------------------------------
+
+// formatScalar renders a single non-nested BigQuery value (everything other
+// than RECORD/REPEATED) as a string, using canonical representations for
+// types that previously fell through to fmt.Sprint: TIMESTAMP as RFC3339 at
+// the configured precision, DATE/TIME/DATETIME as their canonical string
+// form, BYTES as base64 and GEOGRAPHY passed through as WKT.
+func formatScalar(val bigquery.Value, field *bigquery.FieldSchema, precision timestampPrecision) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.Format(precision.layout())
+	case civil.Date:
+		return v.String()
+	case civil.Time:
+		return v.String()
+	case civil.DateTime:
+		return v.String()
+	case *big.Rat:
+		if field != nil {
+			switch field.Type {
+			case bigquery.NumericFieldType:
+				return strings.TrimRight(strings.TrimRight(bigquery.NumericString(v), "0"), ".")
+			case bigquery.BigNumericFieldType:
+				return strings.TrimRight(strings.TrimRight(bigquery.BigNumericString(v), "0"), ".")
+			}
+		}
+		return fmt.Sprint(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+//---------------------------------------------------------------------------------------
+
+// elementField returns the FieldSchema describing a single element of a
+// repeated field, i.e. field with Repeated cleared.
+func elementField(field *bigquery.FieldSchema) *bigquery.FieldSchema {
+	element := *field
+	element.Repeated = false
+	return &element
+}
+
+// jsonNative converts a BigQuery value into the nested Go value (map,
+// slice, or scalar) that encoding/json renders as native JSON, recursing
+// through RECORD/REPEATED fields rather than stringifying them.
+func jsonNative(val bigquery.Value, field *bigquery.FieldSchema, precision timestampPrecision) interface{} {
+	if val == nil {
+		return nil
+	}
+
+	if field != nil && field.Repeated {
+		items, ok := val.([]bigquery.Value)
+		if !ok {
+			return formatScalar(val, field, precision)
+		}
+		element := elementField(field)
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = jsonNative(item, element, precision)
+		}
+		return result
+	}
+
+	if field != nil && field.Type == bigquery.RecordFieldType {
+		nested, ok := val.([]bigquery.Value)
+		if !ok {
+			return formatScalar(val, field, precision)
+		}
+		result := make(map[string]interface{}, len(field.Schema))
+		for i, nestedField := range field.Schema {
+			if i < len(nested) {
+				result[nestedField.Name] = jsonNative(nested[i], nestedField, precision)
+			}
+		}
+		return result
+	}
+
+	switch val.(type) {
+	case string, int64, float64, bool:
+		return val
+	default:
+		return formatScalar(val, field, precision)
+	}
+}
+
+// jsonEncodeValue renders a RECORD/REPEATED value as a JSON-encoded string,
+// the default (non-flatten) representation for delimited output formats.
+func jsonEncodeValue(val bigquery.Value, field *bigquery.FieldSchema, precision timestampPrecision) (string, error) {
+	encoded, err := json.Marshal(jsonNative(val, field, precision))
+	if err != nil {
+		return "", fmt.Errorf("[jsonEncodeValue] Marshal Failed: %w", err)
+	}
+
+	return string(encoded), nil
+}
+
+//---------------------------------------------------------------------------------------
+
+// flatColumn is a single dotted-path column produced by flattenRow, e.g.
+// name "parent.child" or "tags[0].label".
+type flatColumn struct {
+	Name  string
+	Value string
+}
+
+// flattenRow expands a row into dotted-path columns, recursing into nested
+// RECORD fields as "parent.child" and REPEATED fields (scalar or RECORD) as
+// "name[0]", "name[1]", and so on.
+func flattenRow(schema bigquery.Schema, values []bigquery.Value, precision timestampPrecision) []flatColumn {
+	var columns []flatColumn
+	for i, field := range schema {
+		if i >= len(values) {
+			break
+		}
+		columns = append(columns, flattenValue(field.Name, values[i], field, precision)...)
+	}
+
+	return columns
+}
+
+// flattenValue recursively expands a single value under the dotted path
+// name, following the same RECORD/REPEATED rules as flattenRow.
+func flattenValue(name string, val bigquery.Value, field *bigquery.FieldSchema, precision timestampPrecision) []flatColumn {
+	if field == nil {
+		return []flatColumn{{Name: name, Value: formatScalar(val, field, precision)}}
+	}
+
+	if field.Repeated {
+		items, ok := val.([]bigquery.Value)
+		if !ok {
+			return []flatColumn{{Name: name, Value: formatScalar(val, field, precision)}}
+		}
+		element := elementField(field)
+		var columns []flatColumn
+		for i, item := range items {
+			columns = append(columns, flattenValue(fmt.Sprintf("%s[%d]", name, i), item, element, precision)...)
+		}
+		return columns
+	}
+
+	if field.Type == bigquery.RecordFieldType {
+		nested, ok := val.([]bigquery.Value)
+		if !ok {
+			return []flatColumn{{Name: name, Value: formatScalar(val, field, precision)}}
+		}
+		var columns []flatColumn
+		for i, nestedField := range field.Schema {
+			if i < len(nested) {
+				columns = append(columns, flattenValue(name+"."+nestedField.Name, nested[i], nestedField, precision)...)
+			}
+		}
+		return columns
+	}
+
+	return []flatColumn{{Name: name, Value: formatScalar(val, field, precision)}}
+}
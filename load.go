@@ -0,0 +1,272 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+)
+
+var loadHelpText = `
+A command line application designed to provide a simple method to load a
+CSV, JSON Lines or Parquet file from "stdin" or a "gs://" object into a
+BigQuery table, the reverse of the default extract mode.  A detailed log is
+output to the console "stderr" providing you with the available execution
+statistics.
+
+Use --help for more details.
+
+
+USAGE:
+    bq2csv load -p PROJECT_ID -d DATASET -t TABLE < input.csv
+    bq2csv load -p PROJECT_ID -d DATASET -t TABLE gs://bucket/object.csv
+
+ARGS:
+`
+
+// Load carries the configuration and outcome of a single "bq2csv load" run.
+type Load struct {
+	SourceURI        string
+	Error            error
+	LoadStartTime    time.Time
+	LoadEndTime      time.Time
+	TotalRowsLoaded  int64
+	TotalBytesLoaded int64
+}
+
+//---------------------------------------------------------------------------------------
+
+// runLoad implements the "bq2csv load" subcommand, its own flag set mirroring
+// the conventions of the default extract mode.
+func runLoad(args []string) {
+	flags := flag.NewFlagSet("load", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, applicationText, filepath.Base(os.Args[0])+" load", "\n")
+		fmt.Fprint(os.Stderr, copyrightText)
+		fmt.Fprint(os.Stderr, loadHelpText)
+		flags.PrintDefaults()
+	}
+
+	var targetProject = flags.String("p", "", "Google Cloud Project ID  (Required)")
+	var targetDataset = flags.String("d", "", "BigQuery Dataset  (Required)")
+	var targetTable = flags.String("t", "", "BigQuery Table  (Required)")
+	var sourceFormat = flags.String("sf", "csv", "Source File Format  (csv, jsonl, parquet)")
+	var skipLeadingRows = flags.Int64("skip-leading-rows", 0, "Number Of Leading Rows To Skip  (csv)")
+	var maxBadRecords = flags.Int64("max-bad-records", 0, "Maximum Number Of Bad Records Allowed")
+	var allowQuotedNewlines = flags.Bool("allow-quoted-newlines", false, "Allow Quoted Newlines  (csv)")
+	var writeDisposition = flags.String("write-disposition", "append", "Write Disposition  (truncate, append, empty)")
+	var autodetect = flags.Bool("autodetect", false, "Autodetect The Table Schema From The Source Data")
+	var verbose = flags.Bool("v", false, "Output Verbose Detail")
+	var logFormat = flags.String("log-format", "console", "Log Format  (console, json)")
+
+	if err := flags.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	// Validate the Required Flags
+	if *targetProject == "" || *targetDataset == "" || *targetTable == "" {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	// Validate the Log Format
+	switch *logFormat {
+	case "console", "json":
+	default:
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	// Setup Zero Log for Console Output
+	setupLogger(*verbose, *logFormat)
+
+	// Output Header
+	logger.Info().Msgf(applicationText, filepath.Base(os.Args[0])+" load", "")
+	logger.Info().Msg("Arguments")
+	logger.Info().Str("Project ID", *targetProject).Msg(indent)
+	logger.Info().Str("Dataset", *targetDataset).Msg(indent)
+	logger.Info().Str("Table", *targetTable).Msg(indent)
+	logger.Info().Str("Source Format", *sourceFormat).Msg(indent)
+	logger.Info().Int64("Skip Leading Rows", *skipLeadingRows).Msg(indent)
+	logger.Info().Int64("Max Bad Records", *maxBadRecords).Msg(indent)
+	logger.Info().Bool("Allow Quoted Newlines", *allowQuotedNewlines).Msg(indent)
+	logger.Info().Str("Write Disposition", *writeDisposition).Msg(indent)
+	logger.Info().Bool("Autodetect", *autodetect).Msg(indent)
+	logger.Info().Msg("Begin")
+
+	// The positional argument, if present, is a "gs://" source URI. With no
+	// positional argument the source file is read from STDIN.
+	var load Load
+	if flags.NArg() > 0 {
+		load.SourceURI = flags.Arg(0)
+	}
+
+	err := load.ExecuteLoad(*targetProject, *targetDataset, *targetTable, *sourceFormat, *skipLeadingRows, *maxBadRecords, *allowQuotedNewlines, *writeDisposition, *autodetect)
+	load.LogExecuteLoad()
+	if err != nil {
+		logger.Error().Err(err).Msg("Load Failed")
+		os.Exit(1)
+	}
+	logger.Info().Msg("End")
+}
+
+//---------------------------------------------------------------------------------------
+
+// ExecuteLoad loads SourceURI (or STDIN when empty) into project.dataset.table.
+func (load *Load) ExecuteLoad(project string, dataset string, table string, sourceFormat string, skipLeadingRows int64, maxBadRecords int64, allowQuotedNewlines bool, writeDisposition string, autodetect bool) error {
+	ctx := context.Background()
+
+	// Establish a BigQuery Client Connection
+	logger.Info().Msg("Establishing a BigQuery Client Connection")
+	client, err := bigquery.NewClient(ctx, project)
+	if err != nil {
+		return fmt.Errorf("failed establishing a BigQuery client connection: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	disposition, err := parseWriteDisposition(writeDisposition)
+	if err != nil {
+		load.Error = err
+		return err
+	}
+
+	source, err := load.buildSource(sourceFormat, skipLeadingRows, maxBadRecords, allowQuotedNewlines, autodetect)
+	if err != nil {
+		load.Error = err
+		return err
+	}
+
+	loader := client.Dataset(dataset).Table(table).LoaderFrom(source)
+	loader.WriteDisposition = disposition
+
+	load.LoadStartTime = time.Now()
+	job, err := loader.Run(ctx)
+	if err != nil {
+		load.Error = fmt.Errorf("[ExecuteLoad] Load Job Submission Failed: %w", err)
+		return load.Error
+	}
+
+	status, err := job.Wait(ctx)
+	if err != nil {
+		load.Error = fmt.Errorf("[ExecuteLoad] Load Job Wait Failed: %w", err)
+		return load.Error
+	}
+	load.LoadEndTime = time.Now()
+	if err := status.Err(); err != nil {
+		load.Error = fmt.Errorf("[ExecuteLoad] Load Job Failed: %w", err)
+		return load.Error
+	}
+
+	if details, ok := status.Statistics.Details.(*bigquery.LoadStatistics); ok {
+		load.TotalRowsLoaded = details.OutputRows
+		load.TotalBytesLoaded = details.OutputBytes
+	}
+
+	return nil
+}
+
+// buildSource constructs the bigquery.LoadSource for either STDIN or a
+// "gs://" object, sharing the CSV/JSON/Parquet source format handling.
+func (load *Load) buildSource(sourceFormat string, skipLeadingRows int64, maxBadRecords int64, allowQuotedNewlines bool, autodetect bool) (bigquery.LoadSource, error) {
+	format, err := parseSourceFormat(sourceFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if load.SourceURI == "" {
+		reader := bigquery.NewReaderSource(os.Stdin)
+		configureCSVOptions(reader, format, skipLeadingRows, maxBadRecords, allowQuotedNewlines, autodetect)
+		return reader, nil
+	}
+
+	gcsRef := bigquery.NewGCSReference(load.SourceURI)
+	gcsRef.SourceFormat = format
+	gcsRef.MaxBadRecords = maxBadRecords
+	gcsRef.AllowQuotedNewlines = allowQuotedNewlines
+	gcsRef.AutoDetect = autodetect
+	if format == bigquery.CSV {
+		gcsRef.SkipLeadingRows = skipLeadingRows
+	}
+
+	return gcsRef, nil
+}
+
+// configureCSVOptions applies the source format and load options to a
+// ReaderSource. SkipLeadingRows is set only for CSV sources; MaxBadRecords,
+// AllowQuotedNewlines and AutoDetect are set for every source format.
+func configureCSVOptions(reader *bigquery.ReaderSource, format bigquery.DataFormat, skipLeadingRows int64, maxBadRecords int64, allowQuotedNewlines bool, autodetect bool) {
+	reader.SourceFormat = format
+	reader.MaxBadRecords = maxBadRecords
+	reader.AllowQuotedNewlines = allowQuotedNewlines
+	reader.AutoDetect = autodetect
+	if format == bigquery.CSV {
+		reader.SkipLeadingRows = skipLeadingRows
+	}
+}
+
+// parseSourceFormat maps the -sf flag value to a bigquery.DataFormat.
+func parseSourceFormat(sourceFormat string) (bigquery.DataFormat, error) {
+	switch strings.ToLower(sourceFormat) {
+	case "csv":
+		return bigquery.CSV, nil
+	case "jsonl", "json":
+		return bigquery.JSON, nil
+	case "parquet":
+		return bigquery.Parquet, nil
+	default:
+		return "", fmt.Errorf("[parseSourceFormat] Unsupported Source Format: %s", sourceFormat)
+	}
+}
+
+// parseWriteDisposition maps the --write-disposition flag value to a
+// bigquery.TableWriteDisposition.
+func parseWriteDisposition(writeDisposition string) (bigquery.TableWriteDisposition, error) {
+	switch strings.ToLower(writeDisposition) {
+	case "truncate":
+		return bigquery.WriteTruncate, nil
+	case "append":
+		return bigquery.WriteAppend, nil
+	case "empty":
+		return bigquery.WriteEmpty, nil
+	default:
+		return "", fmt.Errorf("[parseWriteDisposition] Unsupported Write Disposition: %s", writeDisposition)
+	}
+}
+
+//---------------------------------------------------------------------------------------
+
+// LogExecuteLoad outputs the load execution statistics to the log.
+func (load *Load) LogExecuteLoad() {
+	logger.Info().Msg("Load Execution")
+
+	// Output Error Message if one exists, but nothing else
+	if load.Error != nil {
+		logger.Error().Err(load.Error).Msg(indent)
+		return
+	}
+
+	logger.Info().Time("Load Execution Start", load.LoadStartTime).Msg(indent)
+	logger.Info().Time("Load Execution End", load.LoadEndTime).Msg(indent)
+	logger.Info().TimeDiff("Execution Time (ms)", load.LoadEndTime, load.LoadStartTime).Msg(indent)
+	logger.Info().Int64("Total Rows Loaded", load.TotalRowsLoaded).Msg(indent)
+	logger.Info().Int64("Total Bytes Loaded", load.TotalBytesLoaded).Msg(indent)
+}
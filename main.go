@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -31,20 +32,37 @@ var indent = "..."
 
 var helpText = `
 A command line application designed to provide a simple method to execute a
-BigQuery SQL script from "stdin", outputting all results to "stdout" in CSV
-format.  A detailed log is output to the console "stderr" providing you with
-the available execution statistics.
+BigQuery SQL script from "stdin", outputting all results to "stdout" in CSV,
+TSV, JSON Lines, Avro or Parquet format.  A detailed log is output to the
+console "stderr" providing you with the available execution statistics.
+
+Run "bq2csv load" for the reverse, loading a CSV/JSON/Parquet file into a
+BigQuery table.  Use "bq2csv load --help" for its own set of flags.
 
 Use --help for more details.
 
 
 USAGE:
     bq2csv -p PROJECT_ID -d DATASET
+    bq2csv load -p PROJECT_ID -d DATASET -t TABLE
 
 ARGS:
 `
 
 func main() {
+	// The "load" subcommand inverts the default extract flow, so it is
+	// dispatched before the extract flags are defined and parsed.
+	if len(os.Args) > 1 && os.Args[1] == "load" {
+		runLoad(os.Args[2:])
+		return
+	}
+
+	runExtract()
+}
+
+// runExtract implements the default extract mode: execute a BigQuery SQL
+// script read from "stdin" and write the results to "stdout".
+func runExtract() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, applicationText, filepath.Base(os.Args[0]), "\n")
 		fmt.Fprint(os.Stderr, copyrightText)
@@ -60,6 +78,17 @@ func main() {
 	var disableQueryCache = flag.Bool("c", false, "Disable Query Cache")
 	var dryRun = flag.Bool("dr", false, "Dry Run")
 	var verbose = flag.Bool("v", false, "Output Verbose Detail")
+	var outputFormat = flag.String("o", "csv", "Output Format  (csv, tsv, jsonl, avro, parquet)")
+	flag.StringVar(outputFormat, "output", "csv", "Output Format  (csv, tsv, jsonl, avro, parquet)")
+	var header = flag.Bool("header", false, "Emit A Schema-Derived Header Row  (csv, tsv)")
+	var storageAPI = flag.Bool("storage-api", false, "Read Results Via The BigQuery Storage Read API")
+	var streams = flag.Int("streams", 4, "Number Of Parallel Storage Read API Streams  (-storage-api)")
+	var logFormat = flag.String("log-format", "console", "Log Format  (console, json)")
+	var flatten = flag.Bool("flatten", false, "Flatten RECORD/REPEATED Fields Into Dotted-Path Columns  (csv, tsv)")
+	var timestampPrecisionFlag = flag.String("timestamp-precision", "micros", "TIMESTAMP Fractional Second Precision  (seconds, millis, micros, nanos)")
+	var maxRetries = flag.Int("max-retries", 5, "Max Retries For Transient BigQuery Errors During Row Iteration")
+	var retryTimeout = flag.Duration("retry-timeout", 2*time.Minute, "Max Total Time Spent Retrying Transient BigQuery Errors")
+	var rowGroupSize = flag.Int64("row-group-size", defaultParquetRowGroupSize, "Parquet Row Group Size In Bytes  (parquet)")
 
 	// Parse the flags
 	flag.Parse()
@@ -76,18 +105,60 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Setup Zero Log for Consolo Output
-	output := zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}
-	logger = zerolog.New(output).With().Timestamp().Logger()
-	zerolog.TimeFieldFormat = "2006-01-02 15:04:05.000"
-	zerolog.DurationFieldUnit = time.Millisecond
-	zerolog.DurationFieldInteger = true
-	if *verbose {
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	} else {
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	// Validate the Output Format
+	switch *outputFormat {
+	case "csv", "tsv", "jsonl", "json", "avro", "parquet":
+	default:
+		flag.Usage()
+		os.Exit(1)
 	}
 
+	// Validate the Number of Storage Read API Streams
+	if *streams < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Validate the Retry Settings
+	if *maxRetries < 0 || *retryTimeout < 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Validate the Parquet Row Group Size
+	if *rowGroupSize < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Validate the Log Format
+	switch *logFormat {
+	case "console", "json":
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Validate the Timestamp Precision
+	timestampPrecision, err := parseTimestampPrecision(*timestampPrecisionFlag)
+	if err != nil {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Setup Zero Log for Console Output
+	setupLogger(*verbose, *logFormat)
+
+	// Configure OpenTelemetry Tracing, a no-op unless OTEL_EXPORTER_OTLP_*
+	// environment variables are set
+	ctx := context.Background()
+	shutdownTracing, err := initTracing(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("OpenTelemetry Tracing Setup Failed")
+		os.Exit(1)
+	}
+	defer func() { _ = shutdownTracing(ctx) }()
+
 	// Output Header
 	logger.Info().Msgf(applicationText, filepath.Base(os.Args[0]), "")
 	logger.Info().Msg("Arguments")
@@ -97,11 +168,24 @@ func main() {
 	logger.Info().Str("Processing Location", *processingLocation).Msg(indent)
 	logger.Info().Bool("Disable Query Cache", *disableQueryCache).Msg(indent)
 	logger.Info().Bool("Dry Run", *dryRun).Msg(indent)
+	logger.Info().Str("Output Format", *outputFormat).Msg(indent)
+	logger.Info().Bool("Header", *header).Msg(indent)
+	logger.Info().Bool("Storage API", *storageAPI).Msg(indent)
+	if *storageAPI {
+		logger.Info().Int("Streams", *streams).Msg(indent)
+	}
+	logger.Info().Bool("Flatten", *flatten).Msg(indent)
+	logger.Info().Str("Timestamp Precision", *timestampPrecisionFlag).Msg(indent)
+	logger.Info().Int("Max Retries", *maxRetries).Msg(indent)
+	logger.Info().Dur("Retry Timeout", *retryTimeout).Msg(indent)
+	if *outputFormat == "parquet" {
+		logger.Info().Int64("Row Group Size", *rowGroupSize).Msg(indent)
+	}
 	logger.Info().Msg("Begin")
 
 	// Load the BigQuery SQL into memory ready for execution
 	var query Query
-	err := query.ReadStdIn()
+	err = query.ReadStdIn()
 	if err != nil {
 		logger.Error().Err(err).Msg("Check STDIN, No SQL Found")
 		os.Exit(1)
@@ -115,10 +199,33 @@ func main() {
 	logger.Info().Int("SQL Length", len(query.SQL)).Msg("Reading SQL Complete")
 
 	// Execute the SQL outputting results to the StdOut
-	err = query.ExecuteQueries(*targetProject, *targetDataset, *processingLocation, *disableQueryCache, *dryRun, *fieldDelimiter)
+	err = query.ExecuteQueries(ctx, *targetProject, *targetDataset, *processingLocation, *disableQueryCache, *dryRun, *fieldDelimiter, *outputFormat, *header, *storageAPI, *streams, *flatten, timestampPrecision, *rowGroupSize, retryPolicy{maxRetries: *maxRetries, timeout: *retryTimeout})
 	if err != nil {
 		logger.Error().Err(err).Msg("SQL Execution Failed")
 		os.Exit(1)
 	}
 	logger.Info().Msg("End")
 }
+
+//---------------------------------------------------------------------------------------
+
+// setupLogger configures the package-level zerolog Logger, shared by every
+// subcommand. logFormat "json" emits newline-delimited JSON to "stderr"
+// suitable for ingestion by log shippers; anything else uses the default
+// human-readable ConsoleWriter.
+func setupLogger(verbose bool, logFormat string) {
+	if logFormat == "json" {
+		logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+	} else {
+		output := zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}
+		logger = zerolog.New(output).With().Timestamp().Logger()
+	}
+	zerolog.TimeFieldFormat = "2006-01-02 15:04:05.000"
+	zerolog.DurationFieldUnit = time.Millisecond
+	zerolog.DurationFieldInteger = true
+	if verbose {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	} else {
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	}
+}
@@ -15,18 +15,23 @@
 package main
 
 import (
-	"bufio"
 	"context"
-	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
 	"time"
 
 	"cloud.google.com/go/bigquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/iterator"
 )
 
+// rowBatchSize is the number of rows covered by each per-batch tracing span
+// emitted while iterating query results.
+const rowBatchSize = 1000
+
 type Query struct {
 	SQL                  string
 	Error                error
@@ -65,14 +70,18 @@ func (sql *Query) ReadStdIn() error {
 //---------------------------------------------------------------------------------------
 
 // Execute the SQL in BigQuery
-func (sql *Query) ExecuteQueries(project string, dataset string, location string, disableQueryCache bool, dryRun bool, delimiter string) error {
+func (sql *Query) ExecuteQueries(ctx context.Context, project string, dataset string, location string, disableQueryCache bool, dryRun bool, delimiter string, outputFormat string, header bool, storageAPI bool, streams int, flatten bool, precision timestampPrecision, rowGroupSize int64, retry retryPolicy) error {
+	ctx, span := tracer.Start(ctx, "ExecuteQueries")
+	defer span.End()
 
 	// Establish a BigQuery Client Connection
 	logger.Info().Msg("Establishing a BigQuery Client Connection")
-	ctx := context.Background()
 	client, err := bigquery.NewClient(ctx, project)
 	if err != nil {
-		return fmt.Errorf("failed establishing a BigQuery client connection: %w", err)
+		err = fmt.Errorf("failed establishing a BigQuery client connection: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
 	defer func() { _ = client.Close() }()
 
@@ -83,13 +92,18 @@ func (sql *Query) ExecuteQueries(project string, dataset string, location string
 	if dryRun {
 		sql.ExecuteDryRun(ctx, client, project, dataset, location, disableQueryCache)
 		sql.LogExecuteDryRun()
+	} else if storageAPI {
+		sql.ExecuteQueryStorageAPI(ctx, client, project, dataset, location, disableQueryCache, delimiter, outputFormat, header, streams, flatten, precision, rowGroupSize)
+		sql.LogExecuteQuery()
 	} else {
-		sql.ExecuteQuery(ctx, client, project, dataset, location, disableQueryCache, delimiter)
+		sql.ExecuteQuery(ctx, client, project, dataset, location, disableQueryCache, delimiter, outputFormat, header, flatten, precision, rowGroupSize, retry)
 		sql.LogExecuteQuery()
 	}
 
 	// Raise an Error if query execution failed
 	if sql.Error != nil {
+		span.RecordError(sql.Error)
+		span.SetStatus(codes.Error, sql.Error.Error())
 		return fmt.Errorf("one or more queries failed")
 	}
 
@@ -99,7 +113,10 @@ func (sql *Query) ExecuteQueries(project string, dataset string, location string
 //---------------------------------------------------------------------------------------
 
 // Execute Query
-func (sql *Query) ExecuteQuery(ctx context.Context, client *bigquery.Client, project string, dataset string, location string, disableQueryCache bool, delimiter string) {
+func (sql *Query) ExecuteQuery(ctx context.Context, client *bigquery.Client, project string, dataset string, location string, disableQueryCache bool, delimiter string, outputFormat string, header bool, flatten bool, precision timestampPrecision, rowGroupSize int64, retry retryPolicy) {
+	ctx, span := tracer.Start(ctx, "ExecuteQuery")
+	defer span.End()
+
 	// Create and Configure Query
 	q := client.Query(sql.SQL)
 	q.DefaultProjectID = project
@@ -108,46 +125,197 @@ func (sql *Query) ExecuteQuery(ctx context.Context, client *bigquery.Client, pro
 	q.DisableQueryCache = disableQueryCache
 	q.DryRun = false
 
-	// Initiate the Query Job
+	// Initiate the Query Job. q.Run (rather than q.Read) is used so that the
+	// Job is retained for its ID and statistics, which are attached to the
+	// span once the job completes.
 	sql.QueryStartTime = time.Now()
-	it, err := q.Read(ctx)
+	job, err := q.Run(ctx)
+	if err != nil {
+		sql.Error = err
+		recordSpanError(span, err)
+		return
+	}
+	span.SetAttributes(attribute.String("bigquery.job_id", job.ID()))
+
+	// retrySpent is the total time spent backing off between retries of
+	// transient errors, across both the initial read and the row iteration
+	// below; no further retry is made once it reaches retry.timeout.
+	var retrySpent time.Duration
+
+	it, err := readJobWithRetry(ctx, job, retry, &retrySpent)
 	sql.QueryEndTime = time.Now()
 	if err != nil {
 		sql.Error = err
+		recordSpanError(span, err)
 		return
 	}
+	annotateSpanWithJobStats(span, job)
 
-	// Ready the CSV Writer and use a buffered io writer for STDOUT
-	w := csv.NewWriter(bufio.NewWriter(os.Stdout))
-	w.Comma = rune(delimiter[0])
-	defer w.Flush()
+	// Ready the RowWriter for the requested output format, writing to STDOUT
+	w, err := NewRowWriter(outputFormat, os.Stdout, delimiter, header, flatten, precision, rowGroupSize)
+	if err != nil {
+		sql.Error = err
+		recordSpanError(span, err)
+		return
+	}
+	defer func() { _ = w.Close() }()
 
 	var rl RowLoader
 	var rowCount int64
+	var attempt int
+	var headerWritten bool
+	var batchSpan = startRowBatchSpan(ctx, 0)
 	for {
 		err := it.Next(&rl)
-		if rowCount == 0 {
-			sql.FirstRowReturnedTime = time.Now()
+
+		// A retryable failure mid-stream resumes from the last successful
+		// page rather than restarting the whole query. The writer is
+		// flushed first so that output already sent to STDOUT stays valid
+		// even if the process is interrupted before the retry completes.
+		if err != nil && err != iterator.Done && isRetryableQueryError(err) && attempt < retry.maxRetries && retrySpent < retry.timeout {
+			if flusher, ok := w.(rowWriterFlusher); ok {
+				if flushErr := flusher.Flush(); flushErr != nil {
+					sql.Error = flushErr
+					recordSpanError(span, flushErr)
+					batchSpan.End()
+					return
+				}
+			}
+
+			attempt++
+			delay := retryDelay(attempt)
+			logger.Warn().Int("Attempt", attempt).Dur("Delay", delay).Int64("Row", rowCount).Err(err).Msg("Retrying Row Iteration")
+			time.Sleep(delay)
+			retrySpent += delay
+
+			token := it.PageInfo().Token
+			resumed, readErr := job.Read(ctx)
+			if readErr != nil {
+				sql.Error = readErr
+				recordSpanError(span, readErr)
+				batchSpan.End()
+				return
+			}
+			resumed.PageInfo().Token = token
+			it = resumed
+			continue
+		}
+
+		if !headerWritten {
+			headerWritten = true
+			// it.Schema, unlike rl.Schema, is set even when the result
+			// has no rows.
+			if headerErr := w.WriteHeader(it.Schema); headerErr != nil {
+				sql.Error = headerErr
+				recordSpanError(span, headerErr)
+				batchSpan.End()
+				return
+			}
 		}
 		if err == iterator.Done {
 			sql.AllRowsReturnedTime = time.Now()
 			sql.TotalRowsReturned = rowCount
+			batchSpan.End()
 			break
 		}
 		if err != nil {
 			sql.Error = err
+			recordSpanError(span, err)
+			batchSpan.End()
 			return
 		}
-		if err := w.Write(rl.Row); err != nil {
-			sql.Error = fmt.Errorf("failed writing to the output file")
+		if rowCount == 0 {
+			sql.FirstRowReturnedTime = time.Now()
+		}
+		if err := w.WriteRow(rl.Schema, rl.Values); err != nil {
+			sql.Error = fmt.Errorf("failed writing to the output file: %w", err)
+			recordSpanError(span, sql.Error)
+			batchSpan.End()
 			return
 		}
 		rowCount++
+		attempt = 0
+		// Flush at each page boundary, i.e. once the rows of the page
+		// just fetched are all written.
+		if it.PageInfo().Remaining() == 0 {
+			if flusher, ok := w.(rowWriterFlusher); ok {
+				if flushErr := flusher.Flush(); flushErr != nil {
+					sql.Error = flushErr
+					recordSpanError(span, flushErr)
+					batchSpan.End()
+					return
+				}
+			}
+		}
+		if rowCount%rowBatchSize == 0 {
+			batchSpan.End()
+			batchSpan = startRowBatchSpan(ctx, rowCount)
+		}
+	}
+	span.SetAttributes(attribute.Int64("bigquery.total_rows_returned", rowCount))
+}
+
+// readJobWithRetry calls job.Read, retrying with exponential backoff if the
+// initial read fails with a retryable error, up to retry.maxRetries attempts
+// or until *spent, the time spent backing off so far (which this adds to),
+// reaches retry.timeout.
+func readJobWithRetry(ctx context.Context, job *bigquery.Job, retry retryPolicy, spent *time.Duration) (*bigquery.RowIterator, error) {
+	var attempt int
+	for {
+		it, err := job.Read(ctx)
+		if err == nil {
+			return it, nil
+		}
+		if !isRetryableQueryError(err) || attempt >= retry.maxRetries || *spent >= retry.timeout {
+			return nil, err
+		}
+		attempt++
+		delay := retryDelay(attempt)
+		logger.Warn().Int("Attempt", attempt).Dur("Delay", delay).Err(err).Msg("Retrying Job Read")
+		time.Sleep(delay)
+		*spent += delay
 	}
 }
 
 //---------------------------------------------------------------------------------------
 
+// startRowBatchSpan starts a child span covering the next rowBatchSize rows,
+// starting at row offset startRow.
+func startRowBatchSpan(ctx context.Context, startRow int64) trace.Span {
+	_, span := tracer.Start(ctx, "ExecuteQuery.RowBatch")
+	span.SetAttributes(attribute.Int64("bigquery.row_batch_start", startRow))
+	return span
+}
+
+// annotateSpanWithJobStats attaches the BigQuery job's total bytes
+// processed, slot-ms, and cache-hit statistics to span, once available.
+func annotateSpanWithJobStats(span trace.Span, job *bigquery.Job) {
+	status := job.LastStatus()
+	if status == nil || status.Statistics == nil {
+		return
+	}
+
+	queryStats, ok := status.Statistics.Details.(*bigquery.QueryStatistics)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("bigquery.total_bytes_processed", queryStats.TotalBytesProcessed),
+		attribute.Int64("bigquery.slot_ms", queryStats.SlotMillis),
+		attribute.Bool("bigquery.cache_hit", queryStats.CacheHit),
+	)
+}
+
+// recordSpanError records err on span and marks the span's status as an
+// error, matching OpenTelemetry convention for failed operations.
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+//---------------------------------------------------------------------------------------
+
 // Execute Dry Run Query
 func (sql *Query) ExecuteDryRun(ctx context.Context, client *bigquery.Client, project string, dataset string, location string, disableQueryCache bool) {
 	// Create and Configure Query
@@ -0,0 +1,87 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryBaseDelay and retryMaxDelay bound the exponential backoff applied
+// between row iteration retry attempts.
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// retryPolicy bounds how many times, and for how long, ExecuteQuery will
+// retry a failed RowIterator page fetch before giving up.
+type retryPolicy struct {
+	maxRetries int
+	timeout    time.Duration
+}
+
+// isRetryableQueryError reports whether err, returned from a RowIterator
+// page fetch, is transient and worth retrying: BigQuery 5xx/429 responses,
+// Unavailable/Internal gRPC statuses, connection resets and per-attempt
+// context deadlines. iterator.Done is never retryable.
+func isRetryableQueryError(err error) bool {
+	if err == nil || err == iterator.Done {
+		return false
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == 429 || apiErr.Code >= 500
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal:
+		return true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return strings.Contains(err.Error(), "connection reset")
+}
+
+// retryDelay returns the exponential backoff delay for attempt (1-based),
+// jittered by up to ±20% so that concurrent retries don't synchronize.
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay * time.Duration(uint(1)<<uint(attempt-1))
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+
+	jitter := time.Duration(float64(delay) * (rand.Float64()*0.4 - 0.2))
+
+	return delay + jitter
+}
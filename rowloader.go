@@ -1,4 +1,4 @@
-// Copyright 2022, Matthew Winter
+// Copyright 2022-2025, Matthew Winter
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -15,21 +15,20 @@
 package main
 
 import (
-	"fmt"
-	"math/big"
-	"strconv"
-	"strings"
-
 	"cloud.google.com/go/bigquery"
 )
 
-// RowLoader implements bigquery.ValueLoader
+// RowLoader implements bigquery.ValueLoader, capturing each row as its
+// native typed BigQuery values alongside the schema that describes them.
+// Output formatting (CSV, JSON Lines, Avro, Parquet, ...) is left to the
+// RowWriter implementations so that type fidelity (NUMERIC, TIMESTAMP,
+// nested RECORD/REPEATED, ...) survives beyond the text-only CSV case.
 type RowLoader struct {
 	// The BigQuery Schema for the row
 	Schema bigquery.Schema
 
-	// The converted row
-	Row []string
+	// The row values, still in their native BigQuery Go types
+	Values []bigquery.Value
 }
 
 var _ bigquery.ValueLoader = &RowLoader{}
@@ -38,28 +37,8 @@ var _ bigquery.ValueLoader = &RowLoader{}
 
 // Load implements bigquery.ValueLoader.
 func (r *RowLoader) Load(row []bigquery.Value, schema bigquery.Schema) error {
-	r.Row = make([]string, len(row))
+	r.Values = row
 	r.Schema = schema
 
-	for i, val := range row {
-		switch val := val.(type) {
-		case string:
-			r.Row[i] = val
-		case int64:
-			r.Row[i] = strconv.FormatInt(val, 10)
-		case *big.Rat:
-			switch schema[i].Type {
-			case bigquery.NumericFieldType:
-				r.Row[i] = strings.TrimRight(strings.TrimRight(bigquery.NumericString(val), "0"), ".")
-			case bigquery.BigNumericFieldType:
-				r.Row[i] = strings.TrimRight(strings.TrimRight(bigquery.BigNumericString(val), "0"), ".")
-			default:
-				r.Row[i] = fmt.Sprint(val)
-			}
-		default:
-			r.Row[i] = fmt.Sprint(val)
-		}
-	}
-
 	return nil
 }
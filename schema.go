@@ -0,0 +1,174 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// avroType returns the Avro logical/primitive type for a single BigQuery
+// field, wrapped in a ["null", type] union when the field is nullable.
+func avroType(field *bigquery.FieldSchema) (interface{}, error) {
+	var t interface{}
+
+	switch field.Type {
+	case bigquery.StringFieldType, bigquery.GeographyFieldType:
+		t = "string"
+	case bigquery.BytesFieldType:
+		t = "bytes"
+	case bigquery.IntegerFieldType:
+		t = "long"
+	case bigquery.FloatFieldType:
+		t = "double"
+	case bigquery.BooleanFieldType:
+		t = "boolean"
+	case bigquery.TimestampFieldType:
+		t = map[string]interface{}{"type": "long", "logicalType": "timestamp-micros"}
+	case bigquery.DateFieldType:
+		t = map[string]interface{}{"type": "int", "logicalType": "date"}
+	case bigquery.TimeFieldType:
+		t = map[string]interface{}{"type": "long", "logicalType": "time-micros"}
+	case bigquery.DateTimeFieldType:
+		t = "string"
+	case bigquery.NumericFieldType:
+		t = map[string]interface{}{"type": "bytes", "logicalType": "decimal", "precision": 38, "scale": 9}
+	case bigquery.BigNumericFieldType:
+		t = map[string]interface{}{"type": "bytes", "logicalType": "decimal", "precision": 76, "scale": 38}
+	case bigquery.RecordFieldType:
+		record, err := avroRecordSchema(field.Schema, field.Name+"_record")
+		if err != nil {
+			return nil, err
+		}
+		t = record
+	default:
+		return nil, fmt.Errorf("[avroType] Unsupported BigQuery Field Type: %s", field.Type)
+	}
+
+	if field.Repeated {
+		return map[string]interface{}{"type": "array", "items": t}, nil
+	}
+	if !field.Required {
+		return []interface{}{"null", t}, nil
+	}
+
+	return t, nil
+}
+
+// avroRecordSchema translates a BigQuery schema into an Avro record schema,
+// recursing into nested RECORD fields.
+func avroRecordSchema(schema bigquery.Schema, name string) (map[string]interface{}, error) {
+	fields := make([]map[string]interface{}, len(schema))
+	for i, field := range schema {
+		t, err := avroType(field)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = map[string]interface{}{"name": field.Name, "type": t}
+	}
+
+	return map[string]interface{}{
+		"type":   "record",
+		"name":   name,
+		"fields": fields,
+	}, nil
+}
+
+//---------------------------------------------------------------------------------------
+
+// parquetType returns the parquet-go JSON schema node for a single BigQuery
+// field, following the same type mapping as avroType. parquet-go's
+// schema.NewSchemaHandlerFromJSON parses every node from a single "Tag"
+// string of comma-separated "key=value" pairs (see StringToTag in
+// parquet-go's schema/json.go); it does not accept separate JSON keys per
+// attribute, so the tag is built up as tagParts and joined at the end.
+func parquetType(field *bigquery.FieldSchema) (map[string]interface{}, error) {
+	tagParts := []string{"name=" + field.Name}
+	var nestedFields []map[string]interface{}
+
+	switch field.Type {
+	case bigquery.StringFieldType, bigquery.GeographyFieldType, bigquery.DateTimeFieldType:
+		tagParts = append(tagParts, "type=BYTE_ARRAY", "convertedtype=UTF8")
+	case bigquery.BytesFieldType:
+		tagParts = append(tagParts, "type=BYTE_ARRAY")
+	case bigquery.IntegerFieldType:
+		tagParts = append(tagParts, "type=INT64")
+	case bigquery.FloatFieldType:
+		tagParts = append(tagParts, "type=DOUBLE")
+	case bigquery.BooleanFieldType:
+		tagParts = append(tagParts, "type=BOOLEAN")
+	case bigquery.TimestampFieldType:
+		tagParts = append(tagParts, "type=INT64", "convertedtype=TIMESTAMP_MICROS")
+	case bigquery.DateFieldType:
+		tagParts = append(tagParts, "type=INT32", "convertedtype=DATE")
+	case bigquery.TimeFieldType:
+		tagParts = append(tagParts, "type=INT64", "convertedtype=TIME_MICROS")
+	case bigquery.NumericFieldType:
+		tagParts = append(tagParts, "type=BYTE_ARRAY", "convertedtype=DECIMAL", "precision=38", "scale=9")
+	case bigquery.BigNumericFieldType:
+		tagParts = append(tagParts, "type=BYTE_ARRAY", "convertedtype=DECIMAL", "precision=76", "scale=38")
+	case bigquery.RecordFieldType:
+		// parquet-go's schema.NewSchemaHandlerFromJSON treats a node with
+		// no "type=" key as a struct/group, recursing into its "Fields" -
+		// there is no "type=STRUCT" value it accepts.
+		fields := make([]map[string]interface{}, len(field.Schema))
+		for i, nested := range field.Schema {
+			nestedTag, err := parquetType(nested)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = nestedTag
+		}
+		nestedFields = fields
+	default:
+		return nil, fmt.Errorf("[parquetType] Unsupported BigQuery Field Type: %s", field.Type)
+	}
+
+	switch {
+	case field.Repeated:
+		tagParts = append(tagParts, "repetitiontype=REPEATED")
+	case field.Required:
+		tagParts = append(tagParts, "repetitiontype=REQUIRED")
+	default:
+		tagParts = append(tagParts, "repetitiontype=OPTIONAL")
+	}
+
+	node := map[string]interface{}{"Tag": strings.Join(tagParts, ", ")}
+	if nestedFields != nil {
+		node["Fields"] = nestedFields
+	}
+
+	return node, nil
+}
+
+// parquetSchema translates a BigQuery schema into the JSON schema document
+// consumed by parquet-go's schema.NewSchemaHandlerFromJSON.
+func parquetSchema(schema bigquery.Schema) (map[string]interface{}, error) {
+	fields := make([]map[string]interface{}, len(schema))
+	for i, field := range schema {
+		tag, err := parquetType(field)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = tag
+	}
+
+	return map[string]interface{}{
+		"Tag":    "name=root, repetitiontype=REQUIRED",
+		"Fields": fields,
+	}, nil
+}
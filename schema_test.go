@@ -0,0 +1,52 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+// TestParquetSchemaRoundTrip checks that parquetSchema produces a document
+// that parquet-go's own schema.NewSchemaHandlerFromJSON can parse, covering
+// a scalar, a NUMERIC (decimal logical type), a REPEATED field and a nested
+// RECORD - each of which has its own "Tag" string quirk.
+func TestParquetSchemaRoundTrip(t *testing.T) {
+	bqSchema := bigquery.Schema{
+		{Name: "name", Type: bigquery.StringFieldType, Required: false},
+		{Name: "amount", Type: bigquery.NumericFieldType, Required: false},
+		{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+		{Name: "nested", Type: bigquery.RecordFieldType, Required: true, Schema: bigquery.Schema{
+			{Name: "inner", Type: bigquery.IntegerFieldType, Required: true},
+		}},
+	}
+
+	doc, err := parquetSchema(bqSchema)
+	if err != nil {
+		t.Fatalf("parquetSchema() error: %v", err)
+	}
+
+	schemaJSON, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+
+	if _, err := schema.NewSchemaHandlerFromJSON(string(schemaJSON)); err != nil {
+		t.Fatalf("NewSchemaHandlerFromJSON() rejected parquetSchema() output: %v\nschema: %s", err, schemaJSON)
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	bqStorage "cloud.google.com/go/bigquery/storage/apiv1"
+	"cloud.google.com/go/bigquery/storage/apiv1/storagepb"
+	"cloud.google.com/go/civil"
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// storageReadMaxRetries bounds the number of retries performed against a
+// single Storage Read API stream before its rows are given up on.
+const storageReadMaxRetries = 5
+
+// ExecuteQueryStorageAPI runs the SQL as a job as usual, then reads the
+// results via the BigQuery Storage Read API rather than the REST-based
+// tabledata.list row iterator, fanning ReadRows out across up to streams
+// parallel streams for substantially higher throughput on large result
+// sets.
+func (sql *Query) ExecuteQueryStorageAPI(ctx context.Context, client *bigquery.Client, project string, dataset string, location string, disableQueryCache bool, delimiter string, outputFormat string, header bool, streams int, flatten bool, precision timestampPrecision, rowGroupSize int64) {
+	// Create and Configure Query
+	q := client.Query(sql.SQL)
+	q.DefaultProjectID = project
+	q.DefaultDatasetID = dataset
+	q.Location = location
+	q.DisableQueryCache = disableQueryCache
+	q.DryRun = false
+
+	// Run the Query Job and Wait for the Destination Table to be Ready
+	sql.QueryStartTime = time.Now()
+	job, err := q.Run(ctx)
+	if err != nil {
+		sql.Error = err
+		return
+	}
+	jobStatus, err := job.Wait(ctx)
+	if err != nil {
+		sql.Error = err
+		return
+	}
+	if err := jobStatus.Err(); err != nil {
+		sql.Error = err
+		return
+	}
+	sql.QueryEndTime = time.Now()
+
+	jobConfig, err := job.Config()
+	if err != nil {
+		sql.Error = fmt.Errorf("[ExecuteQueryStorageAPI] Job Config Failed: %w", err)
+		return
+	}
+	queryConfig, ok := jobConfig.(*bigquery.QueryConfig)
+	if !ok || queryConfig.Dst == nil {
+		sql.Error = fmt.Errorf("[ExecuteQueryStorageAPI] No Destination Table Found")
+		return
+	}
+	dst := queryConfig.Dst
+
+	meta, err := dst.Metadata(ctx)
+	if err != nil {
+		sql.Error = fmt.Errorf("[ExecuteQueryStorageAPI] Destination Table Metadata Failed: %w", err)
+		return
+	}
+
+	// Establish a Storage Read API Client
+	readClient, err := bqStorage.NewBigQueryReadClient(ctx)
+	if err != nil {
+		sql.Error = fmt.Errorf("[ExecuteQueryStorageAPI] Read Client Creation Failed: %w", err)
+		return
+	}
+	defer func() { _ = readClient.Close() }()
+
+	table := fmt.Sprintf("projects/%s/datasets/%s/tables/%s", dst.ProjectID, dst.DatasetID, dst.TableID)
+	session, err := readClient.CreateReadSession(ctx, &storagepb.CreateReadSessionRequest{
+		Parent: fmt.Sprintf("projects/%s", project),
+		ReadSession: &storagepb.ReadSession{
+			Table:      table,
+			DataFormat: storagepb.DataFormat_AVRO,
+		},
+		MaxStreamCount: int32(streams),
+	})
+	if err != nil {
+		sql.Error = fmt.Errorf("[ExecuteQueryStorageAPI] Create Read Session Failed: %w", err)
+		return
+	}
+
+	// The server may grant fewer streams than requested; carry on with
+	// whatever it returned rather than treating it as an error.
+	if got := len(session.GetStreams()); got < streams {
+		logger.Warn().Int("Requested Streams", streams).Int("Granted Streams", got).Msg(indent)
+	}
+
+	codec, err := goavro.NewCodec(session.GetAvroSchema().GetSchema())
+	if err != nil {
+		sql.Error = fmt.Errorf("[ExecuteQueryStorageAPI] Avro Codec Creation Failed: %w", err)
+		return
+	}
+
+	w, err := NewRowWriter(outputFormat, os.Stdout, delimiter, header, flatten, precision, rowGroupSize)
+	if err != nil {
+		sql.Error = err
+		return
+	}
+	defer func() { _ = w.Close() }()
+
+	if err := w.WriteHeader(meta.Schema); err != nil {
+		sql.Error = err
+		return
+	}
+
+	// Fan out ReadRows across every granted stream, decode each stream's
+	// Avro rows concurrently, and serialize the decoded rows through a
+	// single writer goroutine. Rows are not ordered across streams.
+	rows := make(chan map[string]interface{})
+	errs := make(chan error, len(session.GetStreams()))
+	var wg sync.WaitGroup
+	for _, stream := range session.GetStreams() {
+		wg.Add(1)
+		go func(streamName string) {
+			defer wg.Done()
+			if err := readStreamWithRetry(ctx, readClient, streamName, codec, rows); err != nil {
+				errs <- err
+			}
+		}(stream.GetName())
+	}
+
+	go func() {
+		wg.Wait()
+		close(rows)
+	}()
+
+	var rowCount int64
+	for record := range rows {
+		if rowCount == 0 {
+			sql.FirstRowReturnedTime = time.Now()
+		}
+		values := make([]bigquery.Value, len(meta.Schema))
+		for i, field := range meta.Schema {
+			values[i] = bigQueryValueFromAvro(record[field.Name], field)
+		}
+		if err := w.WriteRow(meta.Schema, values); err != nil {
+			sql.Error = fmt.Errorf("failed writing to the output file: %w", err)
+		}
+		rowCount++
+	}
+	close(errs)
+	for err := range errs {
+		if sql.Error == nil {
+			sql.Error = err
+		}
+	}
+
+	sql.AllRowsReturnedTime = time.Now()
+	sql.TotalRowsReturned = rowCount
+}
+
+// readStreamWithRetry reads every row of a single Storage Read API stream,
+// decoding Avro-encoded rows into rows, retrying with a linearly growing
+// backoff (250ms per consecutive attempt) when the stream fails with a
+// retryable gRPC status.
+func readStreamWithRetry(ctx context.Context, readClient *bqStorage.BigQueryReadClient, streamName string, codec *goavro.Codec, rows chan<- map[string]interface{}) error {
+	var attempt int
+	var offset int64
+
+	for {
+		stream, err := readClient.ReadRows(ctx, &storagepb.ReadRowsRequest{ReadStream: streamName, Offset: offset})
+		if err != nil {
+			return fmt.Errorf("[readStreamWithRetry] ReadRows Failed: %w", err)
+		}
+
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				if !isRetryableStorageError(err) || attempt >= storageReadMaxRetries {
+					return fmt.Errorf("[readStreamWithRetry] Recv Failed: %w", err)
+				}
+				attempt++
+				delay := time.Duration(attempt) * 250 * time.Millisecond
+				logger.Warn().Int("Attempt", attempt).Dur("Delay", delay).Err(err).Msg(indent)
+				time.Sleep(delay)
+				break
+			}
+
+			attempt = 0
+			offset += resp.GetRowCount()
+			buf := resp.GetAvroRows().GetSerializedBinaryRows()
+			for len(buf) > 0 {
+				native, remaining, err := codec.NativeFromBinary(buf)
+				if err != nil {
+					return fmt.Errorf("[readStreamWithRetry] Avro Decode Failed: %w", err)
+				}
+				record, _ := native.(map[string]interface{})
+				rows <- record
+				buf = remaining
+			}
+		}
+	}
+}
+
+// isRetryableStorageError reports whether a Storage Read API error is
+// transient and worth retrying.
+func isRetryableStorageError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Internal:
+		return true
+	default:
+		return false
+	}
+}
+
+// bigQueryValueFromAvro unwraps a goavro-decoded value (including nullable
+// union maps) back into the bigquery.Value representation expected by
+// RowWriter implementations. DATE and TIME need an extra conversion here:
+// goavro decodes the Avro "date" logical type to time.Time and
+// "time-micros"/"time-millis" to time.Duration, neither of which is a type
+// formatScalar/jsonNative special-case, so left alone they render as a full
+// RFC3339 timestamp or a raw Go duration string instead of matching the
+// civil.Date/civil.Time rendering the non-storage-api path produces for the
+// same columns.
+//
+// A non-repeated RECORD also decodes to a map[string]interface{} - keyed by
+// field name, not a single-entry union wrapper - so Repeated and
+// RecordFieldType are checked explicitly and handled before the type switch
+// below, rather than a type switch on val conflating a nullable scalar's
+// union wrapper with a record's field map.
+func bigQueryValueFromAvro(val interface{}, field *bigquery.FieldSchema) bigquery.Value {
+	if val == nil {
+		return nil
+	}
+
+	if field != nil && !field.Required && !field.Repeated {
+		// Nullable fields decode as a single-entry union map, e.g.
+		// {"string": "foo"} or {"long": 5} - or, for a nullable RECORD,
+		// {"field_record": {...}}.
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return val
+		}
+		// The unwrapped value is no longer a union, so it is decoded as if
+		// the field were REQUIRED.
+		unwrapped := *field
+		unwrapped.Required = true
+		for _, inner := range m {
+			return bigQueryValueFromAvro(inner, &unwrapped)
+		}
+		return nil
+	}
+
+	if field != nil && field.Repeated {
+		items, ok := val.([]interface{})
+		if !ok {
+			return val
+		}
+		element := avroElementField(field)
+		values := make([]bigquery.Value, len(items))
+		for i, item := range items {
+			values[i] = bigQueryValueFromAvro(item, element)
+		}
+		return values
+	}
+
+	if field != nil && field.Type == bigquery.RecordFieldType {
+		return bigQueryRecordFromAvro(val, field)
+	}
+
+	switch v := val.(type) {
+	case time.Time:
+		if field != nil && field.Type == bigquery.DateFieldType {
+			return civil.DateOf(v)
+		}
+		return v
+	case time.Duration:
+		if field != nil && field.Type == bigquery.TimeFieldType {
+			return civil.Time{
+				Hour:       int(v / time.Hour),
+				Minute:     int(v / time.Minute % 60),
+				Second:     int(v / time.Second % 60),
+				Nanosecond: int(v % time.Second),
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// bigQueryRecordFromAvro reassembles a non-repeated RECORD's goavro-decoded
+// map[string]interface{} (keyed by field name) into the positional
+// []bigquery.Value shape - one entry per field.Schema, in order - that
+// RowWriter implementations expect for a RECORD column.
+func bigQueryRecordFromAvro(val interface{}, field *bigquery.FieldSchema) bigquery.Value {
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	values := make([]bigquery.Value, len(field.Schema))
+	for i, nested := range field.Schema {
+		values[i] = bigQueryValueFromAvro(m[nested.Name], nested)
+	}
+
+	return values
+}
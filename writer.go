@@ -0,0 +1,261 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// RowWriter is implemented by each supported output format (CSV, TSV, JSON
+// Lines, Avro and Parquet) and is selected at runtime via the -o/--output
+// flag. Callers drive a RowWriter with the schema and typed row values
+// produced by a RowLoader, then Close it once all rows have been written.
+type RowWriter interface {
+	// WriteHeader writes the schema-derived column header. It is a no-op
+	// for formats that have no concept of a header row.
+	WriteHeader(schema bigquery.Schema) error
+
+	// WriteRow writes a single row of typed BigQuery values.
+	WriteRow(schema bigquery.Schema, values []bigquery.Value) error
+
+	// Close flushes any buffered output and releases underlying resources.
+	Close() error
+}
+
+// rowWriterFlusher is implemented by RowWriter implementations that buffer
+// output but can flush it without closing the writer. ExecuteQuery uses this
+// to checkpoint output at row iterator page boundaries, so that a retried,
+// resumed page fetch never duplicates or loses already-written rows.
+type rowWriterFlusher interface {
+	Flush() error
+}
+
+// NewRowWriter constructs the RowWriter for the requested output format.
+// format is matched case-insensitively; an empty format defaults to CSV.
+// flatten and precision only affect the delimited (CSV/TSV) and JSON Lines
+// writers; RECORD/REPEATED fields are natively nested in Avro and Parquet.
+// rowGroupSize only affects the Parquet writer.
+func NewRowWriter(format string, out io.Writer, delimiter string, header bool, flatten bool, precision timestampPrecision, rowGroupSize int64) (RowWriter, error) {
+	switch strings.ToLower(format) {
+	case "", "csv":
+		return newDelimitedRowWriter(out, delimiter, header, flatten, precision)
+	case "tsv":
+		return newDelimitedRowWriter(out, "\t", header, flatten, precision)
+	case "jsonl", "json":
+		return newJSONLRowWriter(out, precision), nil
+	case "avro":
+		return newAvroRowWriter(out)
+	case "parquet":
+		return newParquetRowWriter(out, rowGroupSize)
+	default:
+		return nil, fmt.Errorf("[NewRowWriter] Unsupported Output Format: %s", format)
+	}
+}
+
+//---------------------------------------------------------------------------------------
+
+// delimitedRowWriter writes CSV/TSV output using encoding/csv. RECORD and
+// REPEATED fields are JSON-encoded into a single cell by default, or, when
+// flatten is set, expanded into dotted-path columns ("parent.child",
+// "arr[0].field").
+type delimitedRowWriter struct {
+	bw            *bufio.Writer
+	w             *csv.Writer
+	header        bool
+	headerWritten bool
+	flatten       bool
+	precision     timestampPrecision
+
+	// schema is cached from WriteHeader so that Close can synthesize a
+	// flatten header from the schema alone if no row ever arrived.
+	schema bigquery.Schema
+
+	// columns is the dotted-path column set established from the first
+	// flattened row. Every later row is reconciled onto this fixed set (see
+	// reconcileToHeader) so that a REPEATED field with a different
+	// cardinality than the first row's never produces a ragged CSV.
+	columns []string
+}
+
+// newDelimitedRowWriter constructs a delimitedRowWriter writing to out with
+// the given single-character field delimiter.
+func newDelimitedRowWriter(out io.Writer, delimiter string, header bool, flatten bool, precision timestampPrecision) (*delimitedRowWriter, error) {
+	if len(delimiter) != 1 {
+		return nil, fmt.Errorf("[newDelimitedRowWriter] Field Delimiter Must Be 1 Character")
+	}
+
+	bw := bufio.NewWriter(out)
+	w := csv.NewWriter(bw)
+	w.Comma = rune(delimiter[0])
+
+	return &delimitedRowWriter{bw: bw, w: w, header: header, flatten: flatten, precision: precision}, nil
+}
+
+// WriteHeader implements RowWriter. In flatten mode the column set can only
+// be known once the first row's REPEATED field widths are known, so the
+// header is instead written lazily by the first WriteRow call; schema is
+// cached here regardless so that Close can still produce a header for a
+// zero-row result.
+func (d *delimitedRowWriter) WriteHeader(schema bigquery.Schema) error {
+	d.schema = schema
+
+	if !d.header || d.flatten {
+		return nil
+	}
+
+	names := make([]string, len(schema))
+	for i, field := range schema {
+		names[i] = field.Name
+	}
+
+	if err := d.w.Write(names); err != nil {
+		return fmt.Errorf("[WriteHeader] Write Failed: %w", err)
+	}
+	d.headerWritten = true
+
+	return nil
+}
+
+// WriteRow implements RowWriter.
+func (d *delimitedRowWriter) WriteRow(schema bigquery.Schema, values []bigquery.Value) error {
+	if d.flatten {
+		return d.writeFlattenedRow(schema, values)
+	}
+
+	row := make([]string, len(values))
+	for i, val := range values {
+		var field *bigquery.FieldSchema
+		if i < len(schema) {
+			field = schema[i]
+		}
+		row[i] = d.formatCell(val, field)
+	}
+
+	if err := d.w.Write(row); err != nil {
+		return fmt.Errorf("[WriteRow] Write Failed: %w", err)
+	}
+
+	return nil
+}
+
+// writeFlattenedRow expands a row into dotted-path columns. The column set
+// is established from the first row this is called with and cached in
+// d.columns; every row, including the first, is then reconciled onto that
+// fixed set by reconcileToHeader. Without this, a REPEATED field whose
+// cardinality differs row to row would change the column count from one
+// Write call to the next - encoding/csv does not enforce consistency across
+// calls, so that would silently emit ragged, misaligned CSV rather than
+// erroring. The tradeoff is documented here rather than rejected outright:
+// a later row's extra REPEATED elements are dropped, and a later row
+// missing elements the first row had gets empty cells in their place.
+func (d *delimitedRowWriter) writeFlattenedRow(schema bigquery.Schema, values []bigquery.Value) error {
+	columns := flattenRow(schema, values, d.precision)
+
+	if d.columns == nil {
+		d.columns = make([]string, len(columns))
+		for i, column := range columns {
+			d.columns[i] = column.Name
+		}
+		if d.header {
+			if err := d.w.Write(d.columns); err != nil {
+				return fmt.Errorf("[WriteRow] Header Write Failed: %w", err)
+			}
+			d.headerWritten = true
+		}
+	}
+
+	if err := d.w.Write(d.reconcileToHeader(columns)); err != nil {
+		return fmt.Errorf("[WriteRow] Write Failed: %w", err)
+	}
+
+	return nil
+}
+
+// reconcileToHeader maps this row's flattened columns onto the column set
+// established by the first flattened row (d.columns), so every row written
+// has the same width and column order regardless of its own REPEATED field
+// cardinality. A column d.columns has that this row lacks is emitted empty;
+// a column this row has beyond d.columns is dropped.
+func (d *delimitedRowWriter) reconcileToHeader(columns []flatColumn) []string {
+	values := make(map[string]string, len(columns))
+	for _, column := range columns {
+		values[column.Name] = column.Value
+	}
+
+	row := make([]string, len(d.columns))
+	for i, name := range d.columns {
+		row[i] = values[name]
+	}
+
+	return row
+}
+
+// formatCell renders a single cell, JSON-encoding RECORD/REPEATED values
+// and formatting everything else as a canonical scalar string.
+func (d *delimitedRowWriter) formatCell(val bigquery.Value, field *bigquery.FieldSchema) string {
+	if field != nil && (field.Repeated || field.Type == bigquery.RecordFieldType) {
+		encoded, err := jsonEncodeValue(val, field, d.precision)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return encoded
+	}
+
+	return formatScalar(val, field, d.precision)
+}
+
+// Close implements RowWriter. If --header and --flatten were requested but
+// no row ever arrived, writeFlattenedRow never ran and so never wrote a
+// header - unlike the non-flatten path, which always writes one up front in
+// WriteHeader regardless of row count. Make up the gap here by synthesizing
+// a header from the schema alone: a REPEATED field's width cannot be known
+// without a row to measure, so it falls back to flattenRow's single-column,
+// un-indexed treatment of a nil value.
+func (d *delimitedRowWriter) Close() error {
+	if d.flatten && d.header && !d.headerWritten {
+		columns := flattenRow(d.schema, make([]bigquery.Value, len(d.schema)), d.precision)
+		names := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = column.Name
+		}
+		if err := d.w.Write(names); err != nil {
+			return fmt.Errorf("[Close] Header Write Failed: %w", err)
+		}
+		d.headerWritten = true
+	}
+
+	d.w.Flush()
+	if err := d.w.Error(); err != nil {
+		return fmt.Errorf("[Close] Flush Failed: %w", err)
+	}
+
+	return d.bw.Flush()
+}
+
+// Flush implements rowWriterFlusher.
+func (d *delimitedRowWriter) Flush() error {
+	d.w.Flush()
+	if err := d.w.Error(); err != nil {
+		return fmt.Errorf("[Flush] Flush Failed: %w", err)
+	}
+
+	return d.bw.Flush()
+}
@@ -0,0 +1,224 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"github.com/linkedin/goavro/v2"
+)
+
+// avroRowWriter writes an Avro Object Container File (OCF), deriving the
+// record schema from the first call to WriteHeader.
+type avroRowWriter struct {
+	out    io.Writer
+	writer *goavro.OCFWriter
+	codec  *goavro.Codec
+}
+
+// newAvroRowWriter constructs an avroRowWriter that writes an OCF to out.
+// The OCF writer itself is created lazily once the schema is known.
+func newAvroRowWriter(out io.Writer) (*avroRowWriter, error) {
+	return &avroRowWriter{out: out}, nil
+}
+
+// WriteHeader implements RowWriter, initializing the OCF writer with the
+// BigQuery-derived Avro schema.
+func (a *avroRowWriter) WriteHeader(schema bigquery.Schema) error {
+	record, err := avroRecordSchema(schema, "row")
+	if err != nil {
+		return fmt.Errorf("[WriteHeader] Schema Translation Failed: %w", err)
+	}
+
+	schemaJSON, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("[WriteHeader] Schema Marshal Failed: %w", err)
+	}
+
+	codec, err := goavro.NewCodec(string(schemaJSON))
+	if err != nil {
+		return fmt.Errorf("[WriteHeader] Codec Creation Failed: %w", err)
+	}
+
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:               a.out,
+		Codec:           codec,
+		CompressionName: goavro.CompressionSnappyLabel,
+	})
+	if err != nil {
+		return fmt.Errorf("[WriteHeader] OCF Writer Creation Failed: %w", err)
+	}
+
+	a.codec = codec
+	a.writer = writer
+
+	return nil
+}
+
+// WriteRow implements RowWriter.
+func (a *avroRowWriter) WriteRow(schema bigquery.Schema, values []bigquery.Value) error {
+	if a.writer == nil {
+		if err := a.WriteHeader(schema); err != nil {
+			return err
+		}
+	}
+
+	record := make(map[string]interface{}, len(values))
+	for i, val := range values {
+		var field *bigquery.FieldSchema
+		if i < len(schema) {
+			field = schema[i]
+		}
+		record[columnName(field, i)] = avroValue(val, field)
+	}
+
+	if err := a.writer.Append([]interface{}{record}); err != nil {
+		return fmt.Errorf("[WriteRow] Append Failed: %w", err)
+	}
+
+	return nil
+}
+
+// Close implements RowWriter. The OCF writer streams directly to the
+// underlying io.Writer, so there is nothing further to flush here.
+func (a *avroRowWriter) Close() error {
+	return nil
+}
+
+//---------------------------------------------------------------------------------------
+
+// avroValue converts a single typed BigQuery value into the Go native
+// representation expected by goavro for the corresponding Avro type,
+// wrapping nullable fields in the {"type": value} union form goavro
+// requires.
+//
+// A non-repeated RECORD also decodes to []bigquery.Value - one entry per
+// field.Schema, in order - so Repeated and RecordFieldType are checked
+// explicitly and handled before any generic slice case, rather than a type
+// switch on val conflating the two.
+func avroValue(val bigquery.Value, field *bigquery.FieldSchema) interface{} {
+	if val == nil {
+		return nil
+	}
+
+	if field != nil && field.Repeated {
+		items, ok := val.([]bigquery.Value)
+		if !ok {
+			return val
+		}
+		element := avroElementField(field)
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = avroValue(item, element)
+		}
+		return result
+	}
+
+	if field != nil && field.Type == bigquery.RecordFieldType {
+		record := avroRecordValue(val, field)
+		if !field.Required {
+			return map[string]interface{}{avroUnionBranch(field): record}
+		}
+		return record
+	}
+
+	// goavro's "date" and "time-micros" codecs take time.Time and
+	// time.Duration, and DATETIME is written as an Avro string.
+	switch v := val.(type) {
+	case civil.Date:
+		val = v.In(time.UTC)
+	case civil.Time:
+		val = time.Duration(v.Hour)*time.Hour + time.Duration(v.Minute)*time.Minute +
+			time.Duration(v.Second)*time.Second + time.Duration(v.Nanosecond)
+	case civil.DateTime:
+		val = v.String()
+	}
+
+	// *big.Rat (NUMERIC/BIGNUMERIC) is passed through unchanged: goavro's
+	// decimal logical-type codec encodes it directly from the native
+	// *big.Rat, applying the schema's scale itself.
+	if field != nil && !field.Required {
+		return map[string]interface{}{avroUnionBranch(field): val}
+	}
+
+	return val
+}
+
+// avroElementField returns the FieldSchema describing a single element of a
+// repeated field. Repeated is cleared, and Required is forced true: unlike
+// a plain nullable field, avroType never wraps array items in a ["null", t]
+// union regardless of the original field's mode, so avroValue must not
+// union-wrap them either.
+func avroElementField(field *bigquery.FieldSchema) *bigquery.FieldSchema {
+	element := *field
+	element.Repeated = false
+	element.Required = true
+	return &element
+}
+
+// avroRecordValue converts a non-repeated RECORD's positional
+// []bigquery.Value (one entry per field.Schema, in schema order) into the
+// map[string]interface{} goavro expects for a named Avro record, keyed by
+// each child field's name - mirroring jsonNative's equivalent handling of
+// the same positional shape in flatten.go.
+func avroRecordValue(val bigquery.Value, field *bigquery.FieldSchema) map[string]interface{} {
+	fields, _ := val.([]bigquery.Value)
+
+	record := make(map[string]interface{}, len(field.Schema))
+	for i, nested := range field.Schema {
+		if i < len(fields) {
+			record[nested.Name] = avroValue(fields[i], nested)
+		}
+	}
+
+	return record
+}
+
+// avroUnionBranch returns the non-null Avro type name used as the union
+// branch key for a nullable field. Named logical types, such as the NUMERIC/
+// BIGNUMERIC decimal below, are keyed by their logical type name rather than
+// their underlying primitive - goavro registers the decimal codec as
+// "bytes.decimal", not "bytes".
+func avroUnionBranch(field *bigquery.FieldSchema) string {
+	switch field.Type {
+	case bigquery.StringFieldType, bigquery.GeographyFieldType, bigquery.DateTimeFieldType:
+		return "string"
+	case bigquery.BytesFieldType:
+		return "bytes"
+	case bigquery.NumericFieldType, bigquery.BigNumericFieldType:
+		return "bytes.decimal"
+	case bigquery.IntegerFieldType:
+		return "long"
+	case bigquery.TimestampFieldType:
+		return "long.timestamp-micros"
+	case bigquery.TimeFieldType:
+		return "long.time-micros"
+	case bigquery.DateFieldType:
+		return "int.date"
+	case bigquery.FloatFieldType:
+		return "double"
+	case bigquery.BooleanFieldType:
+		return "boolean"
+	case bigquery.RecordFieldType:
+		return field.Name + "_record"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/linkedin/goavro/v2"
+)
+
+// TestAvroRowWriterNumericRoundTrip checks that a NUMERIC value survives a
+// real goavro encode/decode round trip. NUMERIC/BIGNUMERIC both decode to
+// *big.Rat, and goavro's decimal logical-type codec only accepts that type
+// natively - pre-encoding to bytes, or using the wrong union branch name,
+// makes BinaryFromNative reject every non-null row.
+func TestAvroRowWriterNumericRoundTrip(t *testing.T) {
+	schema := bigquery.Schema{
+		{Name: "amount", Type: bigquery.NumericFieldType, Required: false},
+	}
+	want, ok := new(big.Rat).SetString("123.45")
+	if !ok {
+		t.Fatalf("SetString(123.45) failed")
+	}
+
+	var buf bytes.Buffer
+	w, err := newAvroRowWriter(&buf)
+	if err != nil {
+		t.Fatalf("newAvroRowWriter() error: %v", err)
+	}
+	if err := w.WriteRow(schema, []bigquery.Value{want}); err != nil {
+		t.Fatalf("WriteRow() error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	ocfr, err := goavro.NewOCFReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewOCFReader() error: %v", err)
+	}
+	if !ocfr.Scan() {
+		t.Fatalf("expected one record, got none")
+	}
+	rec, err := ocfr.Read()
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+
+	// A nullable field decodes as a single-entry union map keyed by the
+	// branch name, mirroring the shape avroValue encodes it in.
+	union, ok := rec.(map[string]interface{})["amount"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("amount decoded as %T, want a union map", rec.(map[string]interface{})["amount"])
+	}
+	got, ok := union["bytes.decimal"].(*big.Rat)
+	if !ok {
+		t.Fatalf("amount union decoded as %#v, want a \"bytes.decimal\" *big.Rat branch", union)
+	}
+	if got.Cmp(want) != 0 {
+		t.Errorf("amount = %s, want %s", got.RatString(), want.RatString())
+	}
+}
+
+// TestAvroRowWriterStructRoundTrip checks that a non-repeated RECORD
+// survives a real goavro encode/decode round trip. BigQuery represents a
+// RECORD's fields as []bigquery.Value, the same shape used for a REPEATED
+// field's elements - avroValue must key the record by its child field
+// names rather than encoding it as a plain array.
+func TestAvroRowWriterStructRoundTrip(t *testing.T) {
+	schema := bigquery.Schema{
+		{Name: "id", Type: bigquery.IntegerFieldType, Required: true},
+		{Name: "addr", Type: bigquery.RecordFieldType, Required: true, Schema: bigquery.Schema{
+			{Name: "city", Type: bigquery.StringFieldType, Required: true},
+			{Name: "zip", Type: bigquery.IntegerFieldType, Required: false},
+		}},
+	}
+	row := []bigquery.Value{int64(1), []bigquery.Value{"nyc", int64(10001)}}
+
+	var buf bytes.Buffer
+	w, err := newAvroRowWriter(&buf)
+	if err != nil {
+		t.Fatalf("newAvroRowWriter() error: %v", err)
+	}
+	if err := w.WriteRow(schema, row); err != nil {
+		t.Fatalf("WriteRow() error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	ocfr, err := goavro.NewOCFReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewOCFReader() error: %v", err)
+	}
+	if !ocfr.Scan() {
+		t.Fatalf("expected one record, got none")
+	}
+	rec, err := ocfr.Read()
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+
+	addr, ok := rec.(map[string]interface{})["addr"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("addr decoded as %#v, want a record map", rec.(map[string]interface{})["addr"])
+	}
+	if addr["city"] != "nyc" {
+		t.Errorf("addr.city = %v, want nyc", addr["city"])
+	}
+}
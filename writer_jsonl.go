@@ -0,0 +1,83 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// jsonlRowWriter writes newline-delimited JSON (NDJSON/JSON Lines), one
+// object per row keyed by the BigQuery column names. RECORD/REPEATED
+// fields are written as native nested JSON rather than stringified.
+type jsonlRowWriter struct {
+	bw        *bufio.Writer
+	enc       *json.Encoder
+	precision timestampPrecision
+}
+
+// newJSONLRowWriter constructs a jsonlRowWriter writing to out.
+func newJSONLRowWriter(out io.Writer, precision timestampPrecision) *jsonlRowWriter {
+	bw := bufio.NewWriter(out)
+	return &jsonlRowWriter{bw: bw, enc: json.NewEncoder(bw), precision: precision}
+}
+
+// WriteHeader implements RowWriter. JSON Lines has no header concept.
+func (j *jsonlRowWriter) WriteHeader(schema bigquery.Schema) error {
+	return nil
+}
+
+// WriteRow implements RowWriter.
+func (j *jsonlRowWriter) WriteRow(schema bigquery.Schema, values []bigquery.Value) error {
+	row := make(map[string]interface{}, len(values))
+	for i, val := range values {
+		var field *bigquery.FieldSchema
+		if i < len(schema) {
+			field = schema[i]
+		}
+		row[columnName(field, i)] = jsonNative(val, field, j.precision)
+	}
+
+	if err := j.enc.Encode(row); err != nil {
+		return fmt.Errorf("[WriteRow] Encode Failed: %w", err)
+	}
+
+	return nil
+}
+
+// Close implements RowWriter.
+func (j *jsonlRowWriter) Close() error {
+	return j.bw.Flush()
+}
+
+// Flush implements rowWriterFlusher.
+func (j *jsonlRowWriter) Flush() error {
+	return j.bw.Flush()
+}
+
+//---------------------------------------------------------------------------------------
+
+// columnName returns the schema-derived column name for position i, falling
+// back to a positional name when the schema is shorter than the row.
+func columnName(field *bigquery.FieldSchema, i int) string {
+	if field != nil {
+		return field.Name
+	}
+	return fmt.Sprintf("col%d", i)
+}
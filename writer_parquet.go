@@ -0,0 +1,210 @@
+// Copyright 2022-2025, Matthew Winter
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	parquetsource "github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// defaultParquetRowGroupSize is the --row-group-size flag's default value.
+const defaultParquetRowGroupSize = 128 * 1024 * 1024
+
+// parquetRowWriter buffers a Parquet file in memory (the column/row-group
+// footer layout requires a seekable sink) and copies it to out on Close.
+type parquetRowWriter struct {
+	out          io.Writer
+	sink         *parquetsource.BufferFile
+	writer       *writer.JSONWriter
+	rowGroupSize int64
+}
+
+// newParquetRowWriter constructs a parquetRowWriter using the given row
+// group size and Snappy compression.
+func newParquetRowWriter(out io.Writer, rowGroupSize int64) (*parquetRowWriter, error) {
+	return &parquetRowWriter{out: out, rowGroupSize: rowGroupSize}, nil
+}
+
+// WriteHeader implements RowWriter, initializing the Parquet writer with
+// the BigQuery-derived schema. Parquet has no separate header row; the
+// schema is carried in the file footer instead.
+func (p *parquetRowWriter) WriteHeader(schema bigquery.Schema) error {
+	schemaDoc, err := parquetSchema(schema)
+	if err != nil {
+		return fmt.Errorf("[WriteHeader] Schema Translation Failed: %w", err)
+	}
+
+	schemaJSON, err := json.Marshal(schemaDoc)
+	if err != nil {
+		return fmt.Errorf("[WriteHeader] Schema Marshal Failed: %w", err)
+	}
+
+	sink := parquetsource.NewBufferFile()
+	w, err := writer.NewJSONWriter(string(schemaJSON), sink, 4)
+	if err != nil {
+		return fmt.Errorf("[WriteHeader] Writer Creation Failed: %w", err)
+	}
+	w.RowGroupSize = p.rowGroupSize
+	w.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	p.sink = sink
+	p.writer = w
+
+	return nil
+}
+
+// WriteRow implements RowWriter.
+func (p *parquetRowWriter) WriteRow(schema bigquery.Schema, values []bigquery.Value) error {
+	if p.writer == nil {
+		if err := p.WriteHeader(schema); err != nil {
+			return err
+		}
+	}
+
+	record := make(map[string]interface{}, len(values))
+	for i, val := range values {
+		var field *bigquery.FieldSchema
+		if i < len(schema) {
+			field = schema[i]
+		}
+		record[columnName(field, i)] = parquetValue(val, field)
+	}
+
+	rowJSON, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("[WriteRow] Row Marshal Failed: %w", err)
+	}
+
+	if err := p.writer.Write(string(rowJSON)); err != nil {
+		return fmt.Errorf("[WriteRow] Write Failed: %w", err)
+	}
+
+	return nil
+}
+
+// Close implements RowWriter, finalizing the Parquet footer and copying
+// the buffered file to the underlying output.
+func (p *parquetRowWriter) Close() error {
+	if p.writer == nil {
+		return nil
+	}
+
+	if err := p.writer.WriteStop(); err != nil {
+		return fmt.Errorf("[Close] Write Stop Failed: %w", err)
+	}
+
+	// BufferFile's read position tracks its write position, so it is sitting
+	// at EOF after WriteStop; rewind before copying or io.Copy reads nothing.
+	if _, err := p.sink.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("[Close] Seek Failed: %w", err)
+	}
+
+	if _, err := io.Copy(p.out, p.sink); err != nil {
+		return fmt.Errorf("[Close] Copy Failed: %w", err)
+	}
+
+	return nil
+}
+
+//---------------------------------------------------------------------------------------
+
+// parquetValue converts a single typed BigQuery value into the JSON
+// representation consumed by parquet-go's JSONWriter for the corresponding
+// Parquet type.
+//
+// A non-repeated RECORD also decodes to []bigquery.Value - one entry per
+// field.Schema, in order - so Repeated and RecordFieldType are checked
+// explicitly and handled before any generic slice case, rather than a type
+// switch on val conflating the two.
+func parquetValue(val bigquery.Value, field *bigquery.FieldSchema) interface{} {
+	if val == nil {
+		return nil
+	}
+
+	if field != nil && field.Repeated {
+		items, ok := val.([]bigquery.Value)
+		if !ok {
+			return val
+		}
+		element := elementField(field)
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = parquetValue(item, element)
+		}
+		return result
+	}
+
+	if field != nil && field.Type == bigquery.RecordFieldType {
+		return parquetRecordValue(val, field)
+	}
+
+	switch v := val.(type) {
+	case *big.Rat:
+		return decimalString(v, field)
+	case time.Time:
+		// INT64 TIMESTAMP_MICROS: microseconds since the Unix epoch
+		return v.UnixMicro()
+	case civil.Date:
+		// INT32 DATE: days since the Unix epoch
+		return v.In(time.UTC).Unix() / 86400
+	case civil.Time:
+		// INT64 TIME_MICROS: microseconds since midnight
+		return int64(v.Hour)*3600000000 + int64(v.Minute)*60000000 + int64(v.Second)*1000000 + int64(v.Nanosecond)/1000
+	case civil.DateTime:
+		return v.String()
+	}
+
+	return val
+}
+
+// parquetRecordValue converts a non-repeated RECORD's positional
+// []bigquery.Value (one entry per field.Schema, in schema order) into the
+// map[string]interface{} keyed by each child field's name that parquet-go's
+// JSON writer expects for a struct/group column.
+func parquetRecordValue(val bigquery.Value, field *bigquery.FieldSchema) map[string]interface{} {
+	fields, _ := val.([]bigquery.Value)
+
+	record := make(map[string]interface{}, len(field.Schema))
+	for i, nested := range field.Schema {
+		if i < len(fields) {
+			record[nested.Name] = parquetValue(fields[i], nested)
+		}
+	}
+
+	return record
+}
+
+// decimalString renders a NUMERIC/BIGNUMERIC value as a fixed-point decimal
+// string at the field's schema scale (9 for NUMERIC, 38 for BIGNUMERIC).
+// parquet-go's JSON writer parses this string itself and applies the scale
+// when packing the DECIMAL column's underlying bytes - it does not accept a
+// pre-encoded byte representation.
+func decimalString(r *big.Rat, field *bigquery.FieldSchema) string {
+	scale := 9
+	if field != nil && field.Type == bigquery.BigNumericFieldType {
+		scale = 38
+	}
+
+	return r.FloatString(scale)
+}